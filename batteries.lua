@@ -31,12 +31,69 @@ for _, alias in ipairs({
   _batteries[alias[2]] = _batteries[alias[1]]
 end
 
+--read-only globals installed by export({protect = true})
+local _protected = nil
+
+--route the given globals through a metatable on _G, so they can be read
+--but assigning to them raises an error at the offending line
+--(locals of the same name are unaffected)
+--
+--the values live only in _protected, reachable through this metatable, so
+--replacing it would silently make them all nil. the metatable is therefore
+--locked with __metatable: a later setmetatable(_G, ...) (strict mode, a
+--sandbox...) raises an error instead, and getmetatable(_G) returns a string.
+local function protect_globals(values)
+  if _protected == nil then
+    _protected = {}
+    local mt = getmetatable(_G) or {}
+    local index, newindex = mt.__index, mt.__newindex
+    mt.__index = function(t, k)
+      local v = _protected[k]
+      if v ~= nil then
+        return v
+      end
+      if type(index) == "function" then
+        return index(t, k)
+      elseif index ~= nil then
+        return index[k]
+      end
+      return nil
+    end
+    mt.__newindex = function(t, k, v)
+      if _protected[k] ~= nil then
+        error(("batteries: cannot assign to protected global '%s'"):format(tostring(k)), 2)
+      end
+      if type(newindex) == "function" then
+        return newindex(t, k, v)
+      elseif newindex ~= nil then
+        newindex[k] = v
+        return
+      end
+      rawset(t, k, v)
+    end
+    mt.__metatable = "batteries: _G metatable is locked by export({protect = true})"
+    setmetatable(_G, mt)
+  end
+  for k, v in pairs(values) do
+    --__newindex only fires for absent keys, so keep them out of _G itself
+    rawset(_G, k, nil)
+    _protected[k] = v
+  end
+end
+
 --easy export globally if required
-function _batteries:export()
+--  pass {protect = true} to make the exported globals read-only: the batteries
+--  modules and aliases, assert, ripairs, and the overlaid table, string and
+--  math. globals installed by anything else (iter's exports(), a global json)
+--  are not covered. protected globals are read through a metamethod, so global
+--  access to them (eg table.insert without a local) gets somewhat slower.
+function _batteries:export(opts)
+  local exported = {}
+
   --export all key strings globally, if doesn't already exist
   for k, v in pairs(self) do
     if _G[k] == nil then
-      _G[k] = v
+      exported[k] = v
     end
   end
 
@@ -51,17 +108,32 @@ function _batteries:export()
   table.shallow_overlay(string, self.stringx)
 
   --overwrite assert wholesale (it's compatible)
-  _G.assert = self.assert
+  exported.assert = self.assert
 
   --like ipairs, but in reverse
-  _G.ripairs = self.tablex.ripairs
+  exported.ripairs = self.tablex.ripairs
+
+  if opts and opts.protect then
+    --builtins we overlay onto; already present, so not picked up above
+    exported.table = table
+    exported.string = string
+    exported.math = math
+    protect_globals(exported)
+  else
+    for k, v in pairs(exported) do
+      --already exported read-only by an earlier call
+      if _protected == nil or _protected[k] == nil then
+        _G[k] = v
+      end
+    end
+  end
 
   return self
 end
 
 setmetatable(_batteries, {
-  __call = function(t)
-    return t:export()
+  __call = function(t, ...)
+    return t:export(...)
   end,
 })
 
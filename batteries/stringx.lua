@@ -272,4 +272,50 @@ function stringx.ends_with(s, suffix)
 	return true
 end
 
+--aliases
+stringx.startswith = stringx.starts_with
+stringx.endswith = stringx.ends_with
+
+--join an ordered table of strings with a separator
+function stringx.join(t, sep)
+	sep = sep or ""
+
+	assert:type(t, "table", "stringx.join - t", 1)
+	assert:type(sep, "string", "stringx.join - sep", 1)
+
+	return table.concat(t, sep)
+end
+
+--replace occurrences of a literal substring (not a pattern!)
+--	replaces at most n occurrences from the left, or all of them if n is nil
+function stringx.replace(s, old, new, n)
+	n = (n ~= nil and n) or math.huge
+
+	assert:type(s, "string", "stringx.replace - s", 1)
+	assert:type(old, "string", "stringx.replace - old", 1)
+	assert:type(new, "string", "stringx.replace - new", 1)
+	assert:type(n, "number", "stringx.replace - n", 1)
+	assert(old:len() > 0, "stringx.replace - old must not be empty")
+
+	local res = {}
+	local i = 1
+	local count = 0
+	while count < n do
+		local j = s:find(old, i, true)
+		if not j then
+			break
+		end
+		table.insert(res, s:sub(i, j - 1))
+		table.insert(res, new)
+		i = j + old:len()
+		count = count + 1
+	end
+	--nothing to do
+	if count == 0 then
+		return s
+	end
+	table.insert(res, s:sub(i, -1))
+	return table.concat(res)
+end
+
 return stringx
@@ -0,0 +1,207 @@
+--
+-- csv.lua
+--
+-- RFC 4180 style CSV parsing and encoding.
+--
+--   csv.parse(str [, opts]) -> rows
+--
+--     Returns an array of rows, each an array of field strings. Quoted fields
+--     may contain delimiters, newlines and doubled quotes. "\n", "\r\n" and
+--     a lone "\r" each end a record; a trailing newline does not add an
+--     empty row, but a blank line in the middle parses as the row {""}.
+--     Raises an error naming the line number on malformed input.
+--
+--   csv.encode(rows [, opts]) -> str
+--
+--     Inverse of parse. Fields containing the delimiter, a quote or a newline
+--     are quoted. Integral numbers are written exactly (as in json.lua),
+--     other values via tostring, nil as "".
+--
+--   opts:
+--     delimiter   single character other than a quote or newline, default ","
+--     header      parse: true to treat the first row as column names and
+--                 return keyed tables instead of arrays
+--                 encode: array of column names; written as the first row,
+--                 and each row is read as a keyed table in that order
+--     newline     encode only, default "\r\n"
+--
+
+local byte, sub, find, gsub, format = string.byte, string.sub, string.find, string.gsub, string.format
+local concat = table.concat
+local floor = math.floor
+
+local QUOTE, CR, LF = byte('"'), byte("\r"), byte("\n")
+
+local csv = { _version = "0.1.0" }
+
+local function delimiter_of(opts)
+  local delim = opts and opts.delimiter or ","
+  assert(type(delim) == "string" and #delim == 1, "csv: delimiter must be a single character")
+  assert(delim ~= '"' and delim ~= "\r" and delim ~= "\n", "csv: delimiter must not be a quote or newline")
+  return delim
+end
+
+local function escape_pattern(s)
+  return (gsub(s, "%p", "%%%0"))
+end
+
+-- LuaJIT's tostring only prints 14 significant digits, which mangles
+-- integral doubles; format them the same way json.lua's num2str does
+local maxexactint = 2^53
+
+local function num2str(v)
+  if v == floor(v) and v - v == 0 then -- v - v is nan for inf
+    if v <= maxexactint and -v <= maxexactint then
+      return format("%.0f", v)
+    end
+    return format("%.17g", v)
+  end
+  return tostring(v)
+end
+
+local function count_lines(s)
+  local _, n = gsub(s, "\n", "")
+  return n
+end
+
+function csv.parse(str, opts)
+  assert(type(str) == "string", "csv: expected a string")
+  local delim = delimiter_of(opts)
+  local d = byte(delim)
+  local special = "[" .. escape_pattern(delim) .. "\r\n\"]"
+  local rows, row = {}, {}
+  local pos, len, line = 1, #str, 1
+
+  if len == 0 then
+    return rows
+  end
+
+  while true do
+    local value
+    if byte(str, pos) == QUOTE then
+      local start_line = line
+      local buf, n = {}, 0
+      pos = pos + 1
+      while true do
+        local q = find(str, '"', pos, true)
+        if not q then
+          error(format("csv: unterminated quoted field starting on line %d", start_line), 2)
+        end
+        n = n + 1
+        buf[n] = sub(str, pos, q - 1)
+        if byte(str, q + 1) == QUOTE then
+          n = n + 1
+          buf[n] = '"'
+          pos = q + 2
+        else
+          pos = q + 1
+          break
+        end
+      end
+      value = concat(buf)
+      line = line + count_lines(value)
+      local c = byte(str, pos)
+      if c ~= nil and c ~= d and c ~= CR and c ~= LF then
+        error(format("csv: unexpected character after closing quote on line %d", line), 2)
+      end
+    else
+      local stop = find(str, special, pos) or len + 1
+      if byte(str, stop) == QUOTE then
+        error(format("csv: unexpected quote in unquoted field on line %d", line), 2)
+      end
+      value = sub(str, pos, stop - 1)
+      pos = stop
+    end
+    row[#row + 1] = value
+
+    local c = byte(str, pos)
+    if c == d then
+      pos = pos + 1
+    else
+      rows[#rows + 1] = row
+      row = {}
+      if c == nil then
+        break
+      end
+      if c == CR and byte(str, pos + 1) == LF then
+        pos = pos + 2
+      else
+        pos = pos + 1
+      end
+      line = line + 1
+      if pos > len then
+        break
+      end
+    end
+  end
+
+  if opts and opts.header then
+    local names = table.remove(rows, 1) or {}
+    for i, r in ipairs(rows) do
+      local keyed = {}
+      for j, name in ipairs(names) do
+        keyed[name] = r[j]
+      end
+      rows[i] = keyed
+    end
+  end
+
+  return rows
+end
+
+function csv.encode(rows, opts)
+  local delim = delimiter_of(opts)
+  local newline = opts and opts.newline or "\r\n"
+  local header = opts and opts.header
+  local special = "[" .. escape_pattern(delim) .. "\r\n\"]"
+  local out, fields = {}, {}
+
+  local function field(v)
+    local s
+    if v == nil then
+      s = ""
+    elseif type(v) == "number" then
+      s = num2str(v)
+    else
+      s = tostring(v)
+    end
+    if find(s, special) then
+      return '"' .. gsub(s, '"', '""') .. '"'
+    end
+    return s
+  end
+
+  local function line(n)
+    out[#out + 1] = concat(fields, delim, 1, n)
+  end
+
+  if header then
+    for i, name in ipairs(header) do
+      fields[i] = field(name)
+    end
+    line(#header)
+  end
+
+  for _, r in ipairs(rows) do
+    local n
+    if header then
+      n = #header
+      for i = 1, n do
+        fields[i] = field(r[header[i]])
+      end
+    else
+      n = #r
+      for i = 1, n do
+        fields[i] = field(r[i])
+      end
+    end
+    line(n)
+  end
+
+  if #out == 0 then
+    return ""
+  end
+  return concat(out, newline) .. newline
+end
+
+return csv
@@ -0,0 +1,133 @@
+--
+-- encoding.lua
+--
+-- Binary-safe base64 (RFC 4648) and hex codecs for LuaJIT.
+--
+--   base64_encode(s)      standard alphabet, padded with "="
+--   base64_decode(s)      standard alphabet, padding required
+--   base64url_encode(s)   URL-safe alphabet ("-", "_"), unpadded
+--   base64url_decode(s)   URL-safe alphabet, padding optional
+--   hex_encode(s)         lowercase hex
+--   hex_decode(s)         accepts upper or lower case
+--
+-- Strings are treated as raw bytes, so NULs and bytes 0x80-0xFF round-trip
+-- exactly. Decoders raise an error naming the offending position rather
+-- than returning partial output.
+--
+
+local bit = require("bit")
+local band, bor, lshift, rshift = bit.band, bit.bor, bit.lshift, bit.rshift
+local byte, char, find, format, gsub = string.byte, string.char, string.find, string.format, string.gsub
+local concat = table.concat
+
+local encoding = { _version = "0.1.0" }
+
+local EQ = byte("=")
+
+local function alphabet(last2)
+  local chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789" .. last2
+  local enc, dec = {}, {}
+  for i = 1, 64 do
+    local c = byte(chars, i)
+    enc[i - 1] = c
+    dec[c] = i - 1
+  end
+  return enc, dec
+end
+
+local std_enc, std_dec = alphabet("+/")
+local url_enc, url_dec = alphabet("-_")
+
+local function base64_encoder(enc, pad)
+  return function(s)
+    assert(type(s) == "string", "encoding: expected a string")
+    local out, n = {}, 0
+    local len = #s
+    for i = 1, len - 2, 3 do
+      local a, b, c = byte(s, i, i + 2)
+      local v = bor(lshift(a, 16), lshift(b, 8), c)
+      n = n + 1
+      out[n] = char(enc[rshift(v, 18)], enc[band(rshift(v, 12), 63)],
+                    enc[band(rshift(v, 6), 63)], enc[band(v, 63)])
+    end
+    local rem = len % 3
+    if rem == 1 then
+      local v = lshift(byte(s, len), 16)
+      out[n + 1] = char(enc[rshift(v, 18)], enc[band(rshift(v, 12), 63)]) .. (pad and "==" or "")
+    elseif rem == 2 then
+      local a, b = byte(s, len - 1, len)
+      local v = bor(lshift(a, 16), lshift(b, 8))
+      out[n + 1] = char(enc[rshift(v, 18)], enc[band(rshift(v, 12), 63)],
+                        enc[band(rshift(v, 6), 63)]) .. (pad and "=" or "")
+    end
+    return concat(out)
+  end
+end
+
+local function base64_decoder(dec, pad_required)
+  return function(s)
+    assert(type(s) == "string", "encoding: expected a string")
+    local len = #s
+    local pad = 0
+    if byte(s, len) == EQ then
+      pad = byte(s, len - 1) == EQ and 2 or 1
+    end
+    if (pad_required or pad > 0) and len % 4 ~= 0 then
+      error(format("encoding: invalid base64 length %d", len), 2)
+    end
+    local body = len - pad
+    if body % 4 == 1 then
+      error(format("encoding: invalid base64 length %d", len), 2)
+    end
+    local out, n = {}, 0
+    local acc, bits = 0, 0
+    for i = 1, body do
+      local d = dec[byte(s, i)]
+      if d == nil then
+        error(format("encoding: invalid base64 character at position %d", i), 2)
+      end
+      acc = bor(lshift(acc, 6), d)
+      bits = bits + 6
+      if bits >= 8 then
+        bits = bits - 8
+        n = n + 1
+        out[n] = char(band(rshift(acc, bits), 255))
+        acc = band(acc, lshift(1, bits) - 1)
+      end
+    end
+    return concat(out)
+  end
+end
+
+encoding.base64_encode = base64_encoder(std_enc, true)
+encoding.base64_decode = base64_decoder(std_dec, true)
+encoding.base64url_encode = base64_encoder(url_enc, false)
+encoding.base64url_decode = base64_decoder(url_dec, false)
+
+local hex_of = {}
+for i = 0, 255 do
+  hex_of[char(i)] = format("%02x", i)
+end
+
+function encoding.hex_encode(s)
+  assert(type(s) == "string", "encoding: expected a string")
+  return (gsub(s, ".", hex_of))
+end
+
+local function hex_byte(h)
+  return char(tonumber(h, 16))
+end
+
+function encoding.hex_decode(s)
+  assert(type(s) == "string", "encoding: expected a string")
+  local bad = find(s, "[^%x]")
+  if bad then
+    error(format("encoding: invalid hex character at position %d", bad), 2)
+  end
+  if #s % 2 ~= 0 then
+    error(format("encoding: odd hex length %d", #s), 2)
+  end
+  return (gsub(s, "%x%x", hex_byte))
+end
+
+return encoding
@@ -0,0 +1,52 @@
+--
+-- err.lua
+--
+-- Error values for the Go-style `return nil, err` convention, as opposed to
+-- raising with error().
+--
+--   err.new(msg [, code]) -> e              tagged error object
+--   err.raise(code, msg [, details])        raise a tagged error object
+--   err.is(v) -> bool                       true if v was made by err
+--
+-- Fields of e: `message` (string), `code` (any, may be nil) and, for
+-- err.raise, `details` (table, may be nil).
+-- tostring(e) gives the message, so e can also be passed to error().
+--
+-- Host marshalling contract:
+--   An error object is a table whose metatable has the field
+--   __name == "batteries.err". An embedding host that calls a function and
+--   finds such a table as the last returned value should treat the call as
+--   a failure carrying `message` (and `code`), rather than as a value. A
+--   raised error stays a raised error, whatever its payload.
+--   When the raised payload is such a table (from err.raise), the host can
+--   read `code`, `message` and `details` from it instead of parsing a string;
+--   `details` should be marshalled like any returned table. Note that a table
+--   payload carries no "file:line:" prefix; the traceback still has it.
+--
+
+local err = { _version = "0.1.0" }
+
+local err_mt = {
+  __name = "batteries.err",
+  __tostring = function(e)
+    return e.message
+  end,
+}
+err.mt = err_mt
+
+function err.new(msg, code)
+  assert(type(msg) == "string", "err.new: message must be a string")
+  return setmetatable({ message = msg, code = code }, err_mt)
+end
+
+function err.raise(code, msg, details)
+  assert(type(msg) == "string", "err.raise: message must be a string")
+  assert(details == nil or type(details) == "table", "err.raise: details must be a table")
+  error(setmetatable({ message = msg, code = code, details = details }, err_mt))
+end
+
+function err.is(v)
+  return type(v) == "table" and getmetatable(v) == err_mt
+end
+
+return err
@@ -799,6 +799,29 @@ local totable = function(gen_x, param_x, state_x)
 end
 methods.totable = method0(totable)
 exports.totable = export0(totable)
+-- drains the whole iterator: bound infinite ones with take() first
+methods.collect = methods.totable
+exports.collect = exports.totable
+
+local collect_map = function(fun, gen_x, param_x, state_x)
+    assert(type(fun) == "function", "invalid first argument to collect_map")
+    jit.flush()
+    local tab, val = {}
+    while true do
+        state_x, val = gen_x(param_x, state_x)
+        if state_x == nil then
+            break
+        end
+        local key = fun(val)
+        if key == nil then
+            error("collect_map: key function returned nil")
+        end
+        tab[key] = val
+    end
+    return tab
+end
+methods.collect_map = method1(collect_map)
+exports.collect_map = export1(collect_map)
 
 local tomap = function(gen_x, param_x, state_x)
     jit.flush()
@@ -824,6 +847,10 @@ local map_gen = function(param, state)
     return call_if_not_empty(fun, gen_x(param_x, state))
 end
 
+-- map and filter are lazy: nothing is evaluated until a terminal such as
+-- reduce or totable pulls a value. Chained stages run per element in the
+-- order written, so each element passes through the whole chain before the
+-- next one is pulled from the source.
 local map = function(fun, gen, param, state)
     return wrap(map_gen, {gen, param, fun}, state)
 end
@@ -873,6 +900,74 @@ end
 methods.intersperse = method1(intersperse)
 exports.intersperse = export1(intersperse)
 
+local chunk_gen = function(param, state)
+    local n, gen_x, param_x = param[1], param[2], param[3]
+    local state_x, done = state[1], state[2]
+    -- never pull again from a source that has already returned nil
+    if done then
+        return nil
+    end
+    local buf, i = {}, 0
+    while i < n do
+        local s, val = gen_x(param_x, state_x)
+        if s == nil then
+            done = true
+            break
+        end
+        state_x = s
+        i = i + 1
+        buf[i] = val
+    end
+    if i == 0 then
+        return nil
+    end
+    return {state_x, done}, buf
+end
+
+-- yields arrays of n elements; the last one may be shorter
+local chunk = function(n, gen, param, state)
+    assert(type(n) == "number" and n > 0, "invalid first argument to chunk")
+    return wrap(chunk_gen, {n, gen, param}, {state, false})
+end
+methods.chunk = method1(chunk)
+exports.chunk = export1(chunk)
+
+local window_gen = function(param, state)
+    local n, gen_x, param_x = param[1], param[2], param[3]
+    local state_x, prev = state[1], state[2]
+    local win, have, val = {}, 0
+    if prev ~= nil then
+        for i=2,n,1 do
+            win[i - 1] = prev[i]
+        end
+        have = n - 1
+    end
+    while have < n do
+        state_x, val = gen_x(param_x, state_x)
+        if state_x == nil then
+            return nil
+        end
+        have = have + 1
+        win[have] = val
+    end
+    -- win stays private to the state; the caller gets a copy it may modify
+    -- (copied in a loop: unpack is capped by the C stack for large n)
+    local copy = {}
+    for i=1,n,1 do
+        copy[i] = win[i]
+    end
+    return {state_x, win}, copy
+end
+
+-- yields sliding windows of exactly n elements, each a new array;
+-- yields nothing at all if the source has fewer than n elements
+local window = function(n, gen, param, state)
+    assert(type(n) == "number" and n > 0, "invalid first argument to window")
+    return wrap(window_gen, {n, gen, param}, {state, nil})
+end
+methods.window = method1(window)
+exports.window = export1(window)
+
 --------------------------------------------------------------------------------
 -- Compositions
 --------------------------------------------------------------------------------
@@ -994,6 +1089,54 @@ end
 methods.chain = chain
 exports.chain = chain
 
+-- call each other
+local flat_map_gen_x
+local flat_map_gen_y = function(param, state_x, gen_y, param_y, state_y, ...)
+    if state_y == nil then
+        local gen_x, param_x = param[2], param[3]
+        return flat_map_gen_x(param, gen_x(param_x, state_x))
+    end
+    return {state_x, gen_y, param_y, state_y}, ...
+end
+
+flat_map_gen_x = function(param, state_x, ...)
+    if state_x == nil then
+        return nil
+    end
+    local fun = param[1]
+    local gen_y, param_y, state_y
+    if fun ~= nil then
+        gen_y, param_y, state_y = rawiter(fun(...))
+    else
+        gen_y, param_y, state_y = rawiter((...))
+    end
+    return flat_map_gen_y(param, state_x, gen_y, param_y, gen_y(param_y, state_y))
+end
+
+local flat_map_gen = function(param, state)
+    local state_x, gen_y, param_y, state_y = state[1], state[2], state[3], state[4]
+    if gen_y == nil then
+        local gen_x, param_x = param[2], param[3]
+        return flat_map_gen_x(param, gen_x(param_x, state_x))
+    end
+    return flat_map_gen_y(param, state_x, gen_y, param_y, gen_y(param_y, state_y))
+end
+
+-- each element (or each fun(element) for flat_map) must be iterable, as for
+-- chain; a scalar such as a number raises "not iterable"
+local flat_map = function(fun, gen, param, state)
+    assert(type(fun) == "function", "invalid first argument to flat_map")
+    return wrap(flat_map_gen, {fun, gen, param}, {state})
+end
+methods.flat_map = method1(flat_map)
+exports.flat_map = export1(flat_map)
+
+local flatten = function(gen, param, state)
+    return wrap(flat_map_gen, {nil, gen, param}, {state})
+end
+methods.flatten = method0(flatten)
+exports.flatten = export0(flatten)
+
 --------------------------------------------------------------------------------
 -- Operators
 --------------------------------------------------------------------------------
@@ -1026,6 +1169,8 @@ local operator = {
     pow = function(a, b) return a ^ b end,
     sub = function(a, b) return a - b end,
     truediv = function(a, b) return a / b end,
+    min = function(a, b) if b < a then return b else return a end end,
+    max = function(a, b) if b > a then return b else return a end end,
 
     ----------------------------------------------------------------------------
     -- String operators
@@ -1041,6 +1186,9 @@ local operator = {
     lor = function(a, b) return a or b end,
     lnot = function(a) return not a end,
     truth = function(a) return not not a end,
+    and_ = function(a, b) return a and b end, -- an alias
+    or_ = function(a, b) return a or b end, -- an alias
+    not_ = function(a) return not a end, -- an alias
 }
 exports.operator = operator
 methods.operator = operator
@@ -184,7 +184,18 @@ end
 
 updatedecpoint()
 
+-- LuaJIT's tostring only prints 14 significant digits, which does not
+-- round-trip integral doubles. Up to 2^53 print them as plain integers;
+-- above that %.17g is exact and avoids hundreds of digits for 1e300.
+local maxexactint = 2^53
+
 local function num2str (num)
+  if num == floor (num) then
+    if num <= maxexactint and -num <= maxexactint then
+      return strformat ("%.0f", num)
+    end
+    return replace(fsub(strformat ("%.17g", num), numfilter, ""), decpoint, ".")
+  end
   return replace(fsub(tostring(num), numfilter, ""), decpoint, ".")
 end
 
@@ -603,6 +614,19 @@ function json.decode (str, pos, nullval, ...)
   return scanvalue (str, pos, nullval, objectmeta, arraymeta)
 end
 
+-- like json.decode, but raises an error instead of returning nil, pos, msg,
+-- and also rejects anything but whitespace after the decoded value
+function json.decode_strict (str, pos, nullval, ...)
+  local obj, endpos, msg = json.decode (str, pos, nullval, ...)
+  if msg then
+    error (msg .. " (at byte " .. endpos .. ")", 2)
+  end
+  if scanwhite (str, endpos) then
+    error ("trailing garbage at byte " .. endpos, 2)
+  end
+  return obj, endpos
+end
+
 function json.use_lpeg ()
   local g = require ("lpeg")
 
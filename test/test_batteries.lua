@@ -0,0 +1,53 @@
+--[[
+	luaunit specs; run from the repo root with `make test`
+	(luaunit.lua is fetched by `make deps`)
+]]
+
+local lu = require("luaunit")
+local stringx = require("batteries.stringx")
+local iter = require("iter")
+
+TestStringxSplit = {}
+
+function TestStringxSplit:test_basic()
+	lu.assertEquals(stringx.split("a,b,c", ","), {"a", "b", "c"})
+	lu.assertEquals(stringx.split("a--b", "--"), {"a", "b"})
+end
+
+function TestStringxSplit:test_empty_separator()
+	lu.assertEquals(stringx.split("abc", ""), {"a", "b", "c"})
+	lu.assertEquals(stringx.split("", ""), {})
+end
+
+function TestStringxSplit:test_trailing_delimiter()
+	lu.assertEquals(stringx.split("a,b,", ","), {"a", "b", ""})
+	lu.assertEquals(stringx.split(",a", ","), {"", "a"})
+	lu.assertEquals(stringx.split("", ","), {""})
+end
+
+TestStringxReplace = {}
+
+function TestStringxReplace:test_literal()
+	lu.assertEquals(stringx.replace("a.b.c", ".", "-"), "a-b-c")
+	lu.assertEquals(stringx.replace("abab", "ab", ""), "")
+	lu.assertEquals(stringx.replace("abc", "x", "y"), "abc")
+end
+
+function TestStringxReplace:test_limit()
+	lu.assertEquals(stringx.replace("aaa", "a", "b", 2), "bba")
+	lu.assertEquals(stringx.replace("aaa", "a", "b", 0), "aaa")
+end
+
+TestIterOp = {}
+
+function TestIterOp:test_max()
+	lu.assertEquals(iter.range(10):reduce(iter.op.max, -math.huge), 10)
+	lu.assertEquals(iter.reduce(iter.op.max, -math.huge, iter.range(-5, 5)), 5)
+end
+
+function TestIterOp:test_min()
+	lu.assertEquals(iter.range(10):reduce(iter.op.min, math.huge), 1)
+	lu.assertEquals(iter.op.min("b", "a"), "a")
+end
+
+os.exit(lu.LuaUnit.run())